@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkmngo-odi/pogo-protos"
+)
+
+// RequestBuilder assembles a []*protos.Request for a Session.Do call,
+// chaining one method per request instead of hand-appending
+// *protos.Request values and re-marshalling their payloads inline.
+type RequestBuilder struct {
+	requests []*protos.Request
+}
+
+// NewRequestBuilder returns an empty RequestBuilder.
+func NewRequestBuilder() *RequestBuilder {
+	return &RequestBuilder{requests: generateRequests()}
+}
+
+// GetPlayer appends a GET_PLAYER request.
+func (b *RequestBuilder) GetPlayer() *RequestBuilder {
+	b.requests = append(b.requests, &protos.Request{
+		RequestType: protos.RequestType_GET_PLAYER,
+	})
+	return b
+}
+
+// GetHatchedEggs appends a GET_HATCHED_EGGS request.
+func (b *RequestBuilder) GetHatchedEggs() *RequestBuilder {
+	b.requests = append(b.requests, &protos.Request{
+		RequestType: protos.RequestType_GET_HATCHED_EGGS,
+	})
+	return b
+}
+
+// GetInventory appends a GET_INVENTORY request for items changed since
+// sinceMs.
+func (b *RequestBuilder) GetInventory(sinceMs int64) *RequestBuilder {
+	message, _ := proto.Marshal(&protos.GetInventoryMessage{
+		LastTimestampMs: sinceMs,
+	})
+	b.requests = append(b.requests, &protos.Request{
+		RequestType:    protos.RequestType_GET_INVENTORY,
+		RequestMessage: message,
+	})
+	return b
+}
+
+// CheckAwardedBadges appends a CHECK_AWARDED_BADGES request.
+func (b *RequestBuilder) CheckAwardedBadges() *RequestBuilder {
+	b.requests = append(b.requests, &protos.Request{
+		RequestType: protos.RequestType_CHECK_AWARDED_BADGES,
+	})
+	return b
+}
+
+// DownloadSettings appends a DOWNLOAD_SETTINGS request carrying hash, the
+// client's last-known settings hash.
+func (b *RequestBuilder) DownloadSettings(hash string) *RequestBuilder {
+	message, _ := proto.Marshal(&protos.DownloadSettingsMessage{
+		Hash: hash,
+	})
+	b.requests = append(b.requests, &protos.Request{
+		RequestType:    protos.RequestType_DOWNLOAD_SETTINGS,
+		RequestMessage: message,
+	})
+	return b
+}
+
+// GetMapObjects appends a GET_MAP_OBJECTS request for cellIDs, each paired
+// with the corresponding entry in sinceMs, around (lat, lon).
+func (b *RequestBuilder) GetMapObjects(cellIDs []uint64, sinceMs []int64, lat, lon float64) *RequestBuilder {
+	message, _ := proto.Marshal(&protos.GetMapObjectsMessage{
+		CellId:           cellIDs,
+		SinceTimestampMs: sinceMs,
+		Latitude:         lat,
+		Longitude:        lon,
+	})
+	b.requests = append(b.requests, &protos.Request{
+		RequestType:    protos.RequestType_GET_MAP_OBJECTS,
+		RequestMessage: message,
+	})
+	return b
+}
+
+// Custom appends a request of requestType carrying the marshalled form of
+// msg, for request types the builder has no dedicated method for.
+func (b *RequestBuilder) Custom(requestType protos.RequestType, msg proto.Message) *RequestBuilder {
+	var message []byte
+	if msg != nil {
+		message, _ = proto.Marshal(msg)
+	}
+	b.requests = append(b.requests, &protos.Request{
+		RequestType:    requestType,
+		RequestMessage: message,
+	})
+	return b
+}
+
+// build returns the assembled requests, in the order they were appended.
+func (b *RequestBuilder) build() []*protos.Request {
+	return b.requests
+}
+
+// ResponseSet is the typed counterpart to a RequestBuilder, returned by
+// Session.Do. It lets callers pull each response out by request type
+// instead of indexing response.Returns and unmarshalling it by hand.
+type ResponseSet struct {
+	requests []*protos.Request
+	envelope *protos.ResponseEnvelope
+}
+
+// returnFor returns the raw Returns entry for the first request of
+// requestType, and whether one was found.
+func (r *ResponseSet) returnFor(requestType protos.RequestType) ([]byte, bool) {
+	for i, req := range r.requests {
+		if req.RequestType == requestType && i < len(r.envelope.Returns) {
+			return r.envelope.Returns[i], true
+		}
+	}
+	return nil, false
+}
+
+// Player unmarshals the GET_PLAYER response, if one was requested.
+func (r *ResponseSet) Player() *protos.GetPlayerResponse {
+	player := &protos.GetPlayerResponse{}
+	if data, ok := r.returnFor(protos.RequestType_GET_PLAYER); ok {
+		proto.Unmarshal(data, player)
+	}
+	return player
+}
+
+// Inventory unmarshals the GET_INVENTORY response, if one was requested.
+func (r *ResponseSet) Inventory() *protos.GetInventoryResponse {
+	inventory := &protos.GetInventoryResponse{}
+	if data, ok := r.returnFor(protos.RequestType_GET_INVENTORY); ok {
+		proto.Unmarshal(data, inventory)
+	}
+	return inventory
+}
+
+// MapObjects unmarshals the GET_MAP_OBJECTS response, if one was requested.
+func (r *ResponseSet) MapObjects() *protos.GetMapObjectsResponse {
+	mapObjects := &protos.GetMapObjectsResponse{}
+	if data, ok := r.returnFor(protos.RequestType_GET_MAP_OBJECTS); ok {
+		proto.Unmarshal(data, mapObjects)
+	}
+	return mapObjects
+}
+
+// HatchedEggs unmarshals the GET_HATCHED_EGGS response, if one was
+// requested.
+func (r *ResponseSet) HatchedEggs() *protos.GetHatchedEggsResponse {
+	hatchedEggs := &protos.GetHatchedEggsResponse{}
+	if data, ok := r.returnFor(protos.RequestType_GET_HATCHED_EGGS); ok {
+		proto.Unmarshal(data, hatchedEggs)
+	}
+	return hatchedEggs
+}
+
+// AwardedBadges unmarshals the CHECK_AWARDED_BADGES response, if one was
+// requested.
+func (r *ResponseSet) AwardedBadges() *protos.CheckAwardedBadgesResponse {
+	awardedBadges := &protos.CheckAwardedBadgesResponse{}
+	if data, ok := r.returnFor(protos.RequestType_CHECK_AWARDED_BADGES); ok {
+		proto.Unmarshal(data, awardedBadges)
+	}
+	return awardedBadges
+}
+
+// DownloadSettings unmarshals the DOWNLOAD_SETTINGS response, if one was
+// requested.
+func (r *ResponseSet) DownloadSettings() *protos.DownloadSettingsResponse {
+	settings := &protos.DownloadSettingsResponse{}
+	if data, ok := r.returnFor(protos.RequestType_DOWNLOAD_SETTINGS); ok {
+		proto.Unmarshal(data, settings)
+	}
+	return settings
+}
+
+// StatusCode returns the ResponseEnvelope's status code.
+func (r *ResponseSet) StatusCode() int32 {
+	return r.envelope.StatusCode
+}
+
+// ApiURL returns the ResponseEnvelope's ApiUrl, set in response to Init's
+// first DOWNLOAD_SETTINGS round-trip.
+func (r *ResponseSet) ApiURL() string {
+	return r.envelope.ApiUrl
+}
+
+// Do issues the requests assembled by b and returns them as a ResponseSet.
+func (s *Session) Do(ctx context.Context, b *RequestBuilder) (*ResponseSet, error) {
+	requests := b.build()
+	envelope, err := s.CallContext(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	return &ResponseSet{requests: requests, envelope: envelope}, nil
+}
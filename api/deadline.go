@@ -0,0 +1,86 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// deadlineTimer implements net.Conn-style read/write deadlines for Session.
+// It holds two independent cancellation channels, swapped out atomically
+// under a mutex whenever a new deadline is set, with a time.AfterFunc
+// closing the relevant channel once the deadline elapses. Callers select on
+// readCancel()/writeCancel() alongside the operation they want to bound.
+type deadlineTimer struct {
+	mu sync.Mutex
+
+	readCancelCh  chan struct{}
+	writeCancelCh chan struct{}
+
+	readTimer  *time.Timer
+	writeTimer *time.Timer
+}
+
+// init must be called before the deadlineTimer is used.
+func (d *deadlineTimer) init() {
+	d.readCancelCh = make(chan struct{})
+	d.writeCancelCh = make(chan struct{})
+}
+
+// readCancel returns the channel that closes when the read deadline expires.
+func (d *deadlineTimer) readCancel() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.readCancelCh
+}
+
+// writeCancel returns the channel that closes when the write deadline
+// expires.
+func (d *deadlineTimer) writeCancel() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.writeCancelCh
+}
+
+// setDeadline stops any previously scheduled timer, installs a fresh cancel
+// channel, and arranges for it to be closed at t (or immediately, if t has
+// already passed). A zero t disables the deadline.
+func (d *deadlineTimer) setDeadline(timer **time.Timer, cancelCh *chan struct{}, t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if *timer != nil {
+		(*timer).Stop()
+	}
+
+	*cancelCh = make(chan struct{})
+	if t.IsZero() {
+		return
+	}
+
+	ch := *cancelCh
+	if delay := time.Until(t); delay <= 0 {
+		close(ch)
+	} else {
+		*timer = time.AfterFunc(delay, func() { close(ch) })
+	}
+}
+
+// SetReadDeadline sets the deadline for future reads performed through the
+// Session. rpc.Client.Request performs a single synchronous write-then-read
+// round-trip rather than exposing separate write and read phases, so in
+// practice this bounds the whole in-flight RPC, the same as
+// SetWriteDeadline. It is provided for net.Conn-style callers that set both
+// unconditionally; a zero value disables the deadline, matching
+// net.Conn.SetReadDeadline.
+func (s *Session) SetReadDeadline(t time.Time) {
+	s.setDeadline(&s.readTimer, &s.readCancelCh, t)
+}
+
+// SetWriteDeadline sets the deadline for future writes performed through the
+// Session, such as issuing an RPC request. Since the round-trip this bounds
+// is the same one SetReadDeadline bounds, either deadline expiring aborts
+// the in-flight call. A zero value disables the deadline, matching
+// net.Conn.SetWriteDeadline.
+func (s *Session) SetWriteDeadline(t time.Time) {
+	s.setDeadline(&s.writeTimer, &s.writeCancelCh, t)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/pkmngo-odi/pogo-protos"
+)
+
+// defaultRequestWeight is charged against a RateLimiter for any RequestType
+// that has no entry in requestWeights.
+const defaultRequestWeight = 1
+
+// requestWeights assigns a token cost to RequestTypes known to be expensive
+// on Niantic's backend, so a single GET_MAP_OBJECTS call can't starve
+// cheaper calls like GET_PLAYER out of the same budget.
+var requestWeights = map[protos.RequestType]int{
+	protos.RequestType_GET_MAP_OBJECTS: 5,
+	protos.RequestType_GET_INVENTORY:   2,
+	protos.RequestType_GET_PLAYER:      1,
+}
+
+func weightFor(requestType protos.RequestType) int {
+	if weight, ok := requestWeights[requestType]; ok {
+		return weight
+	}
+	return defaultRequestWeight
+}
+
+// weightForTypes returns the heaviest weight among requestTypes, since a
+// single physical RPC (Init, Announce, Session.Do) can bundle several
+// protocol requests into one call and should be charged for whichever of
+// them is most expensive, not just whichever happens to be first.
+func weightForTypes(requestTypes []protos.RequestType) int {
+	weight := defaultRequestWeight
+	for _, rt := range requestTypes {
+		if w := weightFor(rt); w > weight {
+			weight = w
+		}
+	}
+	return weight
+}
+
+// RateLimiter bounds how quickly a Session may issue outbound RPC calls, so
+// a client doesn't trip Niantic's soft-ban heuristics. A single RateLimiter
+// can be shared across multiple Sessions to enforce one global budget, so
+// implementations must be safe for concurrent use.
+type RateLimiter interface {
+	// Wait blocks until there is enough budget for an RPC carrying
+	// requestTypes, or ctx is cancelled. requestTypes names every
+	// protocol request bundled into the physical call being bounded;
+	// implementations should charge for the heaviest of them.
+	Wait(ctx context.Context, requestTypes ...protos.RequestType) error
+
+	// Backoff is reported the ResponseEnvelope status code after every
+	// call that completes without error, so the limiter can throttle
+	// harder once Niantic starts returning soft-ban style codes.
+	Backoff(statusCode int32)
+}
+
+// TokenBucketLimiter is the default RateLimiter. It wraps a
+// golang.org/x/time/rate.Limiter weighted per-RequestType, and layers an
+// additional back-off delay on top once Backoff observes a throttling
+// status code.
+type TokenBucketLimiter struct {
+	limiter *rate.Limiter
+
+	mu      sync.Mutex
+	penalty time.Duration
+}
+
+// NewTokenBucketLimiter returns a TokenBucketLimiter that refills at r
+// tokens per second up to a burst of b tokens.
+func NewTokenBucketLimiter(r rate.Limit, b int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{limiter: rate.NewLimiter(r, b)}
+}
+
+// Wait implements RateLimiter.
+func (t *TokenBucketLimiter) Wait(ctx context.Context, requestTypes ...protos.RequestType) error {
+	t.mu.Lock()
+	penalty := t.penalty
+	t.mu.Unlock()
+
+	if penalty > 0 {
+		timer := time.NewTimer(penalty)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return t.limiter.WaitN(ctx, weightForTypes(requestTypes))
+}
+
+// Backoff implements RateLimiter. A throttling status code sets the
+// pre-request penalty to one second, or doubles it if one is already in
+// place, up to a one-minute ceiling; any other code clears it.
+func (t *TokenBucketLimiter) Backoff(statusCode int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch statusCode {
+	case 52, 53: // Niantic throttle/soft-ban style StatusCodes
+		if t.penalty == 0 {
+			t.penalty = time.Second
+		} else {
+			t.penalty *= 2
+		}
+		if t.penalty > time.Minute {
+			t.penalty = time.Minute
+		}
+	default:
+		t.penalty = 0
+	}
+}
+
+// unlimited is the zero-configuration RateLimiter used when NewSession is
+// given a nil limiter: generous enough not to interfere with casual use,
+// but still enough of a ceiling to avoid hammering the API by accident.
+func unlimited() RateLimiter {
+	return NewTokenBucketLimiter(rate.Every(200*time.Millisecond), 10)
+}
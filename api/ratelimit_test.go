@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkmngo-odi/pogo-protos"
+)
+
+func TestTokenBucketLimiterBackoff(t *testing.T) {
+	limiter := NewTokenBucketLimiter(1, 1)
+
+	limiter.Backoff(52)
+	if limiter.penalty != time.Second {
+		t.Fatalf("penalty = %v, want %v after first throttle", limiter.penalty, time.Second)
+	}
+
+	limiter.Backoff(52)
+	if limiter.penalty != 2*time.Second {
+		t.Fatalf("penalty = %v, want %v after second throttle", limiter.penalty, 2*time.Second)
+	}
+
+	for i := 0; i < 10; i++ {
+		limiter.Backoff(52)
+	}
+	if limiter.penalty != time.Minute {
+		t.Fatalf("penalty = %v, want ceiling %v after repeated throttling", limiter.penalty, time.Minute)
+	}
+
+	limiter.Backoff(1)
+	if limiter.penalty != 0 {
+		t.Fatalf("penalty = %v, want 0 after a non-throttle status code", limiter.penalty)
+	}
+}
+
+func TestWeightForTypes(t *testing.T) {
+	if got, want := weightForTypes(nil), defaultRequestWeight; got != want {
+		t.Fatalf("weightForTypes(nil) = %d, want %d", got, want)
+	}
+
+	// GET_MAP_OBJECTS isn't first in the batch, but should still
+	// dominate the weight charged for the whole physical call.
+	batch := []protos.RequestType{
+		protos.RequestType_GET_HATCHED_EGGS,
+		protos.RequestType_GET_MAP_OBJECTS,
+		protos.RequestType_GET_PLAYER,
+	}
+	if got, want := weightForTypes(batch), requestWeights[protos.RequestType_GET_MAP_OBJECTS]; got != want {
+		t.Fatalf("weightForTypes(%v) = %d, want %d (heaviest type in the batch)", batch, got, want)
+	}
+}
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkmngo-odi/pogo-protos"
+)
+
+// earthRadiusMeters is used to convert a scan radius in meters to an s1.Angle
+// for s2.CapFromCenterAngle, and to turn angular distances back into meters when
+// enforcing Criteria.MinSpacingMeters.
+const earthRadiusMeters = 6371010.0
+
+// Region describes the area a Scanner should cover: either a circle
+// (Center+RadiusMeters) or a lat/lng rectangle (SouthWest/NorthEast).
+type Region struct {
+	Center       *Location
+	RadiusMeters float64
+
+	SouthWest *Location
+	NorthEast *Location
+}
+
+func (r Region) region() s2.Region {
+	if r.Center != nil {
+		center := s2.PointFromLatLng(s2.LatLngFromDegrees(r.Center.Lat, r.Center.Lon))
+		return s2.CapFromCenterAngle(center, s1.Angle(r.RadiusMeters/earthRadiusMeters))
+	}
+	return s2.RectFromLatLng(s2.LatLngFromDegrees(r.SouthWest.Lat, r.SouthWest.Lon)).AddPoint(
+		s2.LatLngFromDegrees(r.NorthEast.Lat, r.NorthEast.Lon))
+}
+
+// Criteria constrains which Pokémon and forts a Scan returns. Several
+// Criteria can be given to Scan at once; a result must satisfy all of them.
+// The zero Criteria returns everything the covering sees, deduplicated but
+// unfiltered.
+type Criteria struct {
+	// MinSpacingMeters discards a Pokémon if another, already-returned
+	// Pokémon sighting is closer than this many meters away.
+	MinSpacingMeters float64
+
+	// MaxPerCell caps how many Pokémon are kept per covering cell,
+	// counting only those that pass every other filter, MinSpacingMeters
+	// included.
+	MaxPerCell int
+
+	// PokemonIDs, if non-empty, restricts Pokémon results to this set.
+	PokemonIDs map[int32]bool
+
+	// ExcludeGyms and ExcludePokestops drop the corresponding fort kind
+	// entirely.
+	ExcludeGyms      bool
+	ExcludePokestops bool
+}
+
+func (c Criteria) allows(p *protos.WildPokemon) bool {
+	if len(c.PokemonIDs) == 0 {
+		return true
+	}
+	return c.PokemonIDs[int32(p.Pokemon.PokemonId)]
+}
+
+// Pokemon is a wild Pokémon sighting returned by a Scan.
+type Pokemon struct {
+	Location Location
+	Data     *protos.WildPokemon
+}
+
+// Pokestop is a PokéStop returned by a Scan.
+type Pokestop struct {
+	Location Location
+	Data     *protos.FortData
+}
+
+// Gym is a Gym returned by a Scan.
+type Gym struct {
+	Location Location
+	Data     *protos.FortData
+}
+
+// Results holds the channels a Scan streams typed results over. Each
+// channel is closed once the scan completes or its context is cancelled.
+type Results struct {
+	Pokemon   <-chan Pokemon
+	Pokestops <-chan Pokestop
+	Gyms      <-chan Gym
+}
+
+// Scanner sweeps a Region for map objects by decomposing it into a covering
+// of level-15 S2 cells and issuing batched GET_MAP_OBJECTS calls across the
+// covering, honouring the Session's RateLimiter. It remembers the last seen
+// timestamp per cell, so repeated Scans over the same area only request
+// deltas.
+type Scanner struct {
+	session *Session
+
+	mu      sync.Mutex
+	sinceMs map[s2.CellID]int64
+}
+
+// NewScanner returns a Scanner that issues its requests through session.
+func NewScanner(session *Session) *Scanner {
+	return &Scanner{
+		session: session,
+		sinceMs: make(map[s2.CellID]int64),
+	}
+}
+
+// scanBatchSize is the number of cell IDs bundled into a single
+// GET_MAP_OBJECTS request, matching the batch size the pre-existing
+// getCellIDs/Announce path already uses.
+const scanBatchSize = 5
+
+// batchCellIDs splits cellIDs into consecutive groups of at most size, so a
+// Scan issues one GET_MAP_OBJECTS request per group instead of one per cell.
+func batchCellIDs(cellIDs []s2.CellID, size int) [][]s2.CellID {
+	var batches [][]s2.CellID
+	for i := 0; i < len(cellIDs); i += size {
+		end := i + size
+		if end > len(cellIDs) {
+			end = len(cellIDs)
+		}
+		batches = append(batches, cellIDs[i:end])
+	}
+	return batches
+}
+
+// Scan covers region with level-15 S2 cells and, in a background goroutine,
+// issues batched GET_MAP_OBJECTS requests across the covering, streaming
+// deduplicated results matching every given Criteria onto the returned
+// Results channels as they're found. Scan itself returns immediately; all
+// three channels are closed once the sweep finishes or ctx is done.
+func (sc *Scanner) Scan(ctx context.Context, region Region, criteria ...Criteria) Results {
+	pokemonCh := make(chan Pokemon)
+	pokestopCh := make(chan Pokestop)
+	gymCh := make(chan Gym)
+
+	coverer := &s2.RegionCoverer{MinLevel: cellIDLevel, MaxLevel: cellIDLevel, MaxCells: 64}
+	batches := batchCellIDs(coverer.Covering(region.region()), scanBatchSize)
+
+	go func() {
+		defer close(pokemonCh)
+		defer close(pokestopCh)
+		defer close(gymCh)
+
+		seenPokemon := make(map[uint64]bool)
+		seenForts := make(map[string]bool)
+		var emittedPokemon []Pokemon
+
+		for _, batch := range batches {
+			if ctx.Err() != nil {
+				return
+			}
+
+			cellIDs := make([]uint64, len(batch))
+			sinceMs := make([]int64, len(batch))
+			sc.mu.Lock()
+			for i, cellID := range batch {
+				cellIDs[i] = uint64(cellID)
+				sinceMs[i] = sc.sinceMs[cellID]
+			}
+			sc.mu.Unlock()
+
+			response, err := sc.session.CallContext(ctx, []*protos.Request{requestGetMapObjects(sc.session, cellIDs, sinceMs)})
+			if err != nil {
+				return
+			}
+
+			mapObjects := &protos.GetMapObjectsResponse{}
+			if err := proto.Unmarshal(response.Returns[0], mapObjects); err != nil {
+				continue
+			}
+
+			now := time.Now().Unix() * 1000
+			sc.mu.Lock()
+			for _, cellID := range batch {
+				sc.sinceMs[cellID] = now
+			}
+			sc.mu.Unlock()
+
+			for _, cell := range mapObjects.MapCells {
+				perCell := 0
+				for _, wild := range cell.WildPokemon {
+					if !matchesAll(criteria, wild) {
+						continue
+					}
+					if perCell == maxPerCell(criteria) && maxPerCell(criteria) > 0 {
+						break
+					}
+					if seenPokemon[wild.EncounterId] {
+						continue
+					}
+					loc := Location{Lat: wild.Latitude, Lon: wild.Longitude}
+					if tooClose(criteria, loc, emittedPokemon) {
+						continue
+					}
+					seenPokemon[wild.EncounterId] = true
+					perCell++
+					p := Pokemon{Location: loc, Data: wild}
+					emittedPokemon = append(emittedPokemon, p)
+					select {
+					case pokemonCh <- p:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				for _, fort := range cell.Fort {
+					if seenForts[fort.Id] {
+						continue
+					}
+					seenForts[fort.Id] = true
+					loc := Location{Lat: fort.Latitude, Lon: fort.Longitude}
+
+					if fort.Type == protos.FortType_GYM {
+						if anyExcludesGyms(criteria) {
+							continue
+						}
+						select {
+						case gymCh <- Gym{Location: loc, Data: fort}:
+						case <-ctx.Done():
+							return
+						}
+						continue
+					}
+
+					if anyExcludesPokestops(criteria) {
+						continue
+					}
+					select {
+					case pokestopCh <- Pokestop{Location: loc, Data: fort}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return Results{Pokemon: pokemonCh, Pokestops: pokestopCh, Gyms: gymCh}
+}
+
+func matchesAll(criteria []Criteria, p *protos.WildPokemon) bool {
+	for _, c := range criteria {
+		if !c.allows(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func maxPerCell(criteria []Criteria) int {
+	limit := 0
+	for _, c := range criteria {
+		if c.MaxPerCell > 0 && (limit == 0 || c.MaxPerCell < limit) {
+			limit = c.MaxPerCell
+		}
+	}
+	return limit
+}
+
+func anyExcludesGyms(criteria []Criteria) bool {
+	for _, c := range criteria {
+		if c.ExcludeGyms {
+			return true
+		}
+	}
+	return false
+}
+
+func anyExcludesPokestops(criteria []Criteria) bool {
+	for _, c := range criteria {
+		if c.ExcludePokestops {
+			return true
+		}
+	}
+	return false
+}
+
+// tooClose reports whether loc is within any applicable MinSpacingMeters of
+// an already-emitted Pokémon sighting.
+func tooClose(criteria []Criteria, loc Location, emitted []Pokemon) bool {
+	for _, c := range criteria {
+		if c.MinSpacingMeters <= 0 {
+			continue
+		}
+		for _, p := range emitted {
+			if distanceMeters(loc, p.Location) < c.MinSpacingMeters {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestGetMapObjects builds a GET_MAP_OBJECTS request for the given cell
+// IDs, each paired with the timestamp of the last scan that covered it.
+func requestGetMapObjects(session *Session, cellIDs []uint64, sinceMs []int64) *protos.Request {
+	message, _ := proto.Marshal(&protos.GetMapObjectsMessage{
+		CellId:           cellIDs,
+		SinceTimestampMs: sinceMs,
+		Longitude:        session.location.Lon,
+		Latitude:         session.location.Lat,
+	})
+	return &protos.Request{
+		RequestType:    protos.RequestType_GET_MAP_OBJECTS,
+		RequestMessage: message,
+	}
+}
+
+// distanceMeters returns the great-circle distance between a and b.
+func distanceMeters(a, b Location) float64 {
+	angle := s2.LatLngFromDegrees(a.Lat, a.Lon).Distance(s2.LatLngFromDegrees(b.Lat, b.Lon))
+	return float64(angle) * earthRadiusMeters
+}
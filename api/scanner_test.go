@@ -0,0 +1,65 @@
+package api
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestDistanceMeters(t *testing.T) {
+	a := Location{Lat: 0, Lon: 0}
+	b := Location{Lat: 0, Lon: 1}
+
+	got := distanceMeters(a, b)
+	want := 111319.5 // ~1 degree of longitude at the equator
+
+	if math.Abs(got-want) > 500 {
+		t.Fatalf("distanceMeters(%v, %v) = %v, want ~%v", a, b, got, want)
+	}
+}
+
+func TestTooClose(t *testing.T) {
+	emitted := []Pokemon{{Location: Location{Lat: 0, Lon: 0}}}
+
+	near := Location{Lat: 0.0001, Lon: 0} // ~11m away
+	far := Location{Lat: 0.01, Lon: 0}    // ~1.1km away
+
+	criteria := []Criteria{{MinSpacingMeters: 50}}
+	if !tooClose(criteria, near, emitted) {
+		t.Fatalf("expected %v to be too close to %v under a 50m criteria", near, emitted[0].Location)
+	}
+	if tooClose(criteria, far, emitted) {
+		t.Fatalf("expected %v not to be too close to %v under a 50m criteria", far, emitted[0].Location)
+	}
+}
+
+func TestTooCloseWithoutSpacingCriteria(t *testing.T) {
+	emitted := []Pokemon{{Location: Location{Lat: 0, Lon: 0}}}
+
+	if tooClose(nil, emitted[0].Location, emitted) {
+		t.Fatalf("expected no Criteria to never reject on spacing")
+	}
+	if tooClose([]Criteria{{}}, emitted[0].Location, emitted) {
+		t.Fatalf("expected a zero-value MinSpacingMeters to never reject on spacing")
+	}
+}
+
+func TestBatchCellIDs(t *testing.T) {
+	cellIDs := make([]s2.CellID, 12)
+	for i := range cellIDs {
+		cellIDs[i] = s2.CellID(i + 1)
+	}
+
+	batches := batchCellIDs(cellIDs, scanBatchSize)
+
+	if got, want := len(batches), 3; got != want {
+		t.Fatalf("len(batches) = %d, want %d", got, want)
+	}
+	if got, want := len(batches[0]), scanBatchSize; got != want {
+		t.Fatalf("len(batches[0]) = %d, want %d", got, want)
+	}
+	if got, want := len(batches[2]), 2; got != want {
+		t.Fatalf("len(batches[2]) = %d, want %d (leftover batch)", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -26,12 +27,15 @@ type Location struct {
 
 // Session is used to communicate with the Pokémon Go API
 type Session struct {
+	deadlineTimer
+
 	location *Location
 
 	url      string
 	rpc      *rpc.Client
 	provider auth.Provider
 	debug    bool
+	limiter  RateLimiter
 }
 
 func generateRequests() []*protos.Request {
@@ -48,14 +52,30 @@ func getCellIDs(location *Location) []uint64 {
 	return cellIDs
 }
 
-// NewSession constructs a Pokémon Go RPC API client
+// NewSession constructs a Pokémon Go RPC API client, rate-limited by a
+// conservative default RateLimiter. Use NewSessionWithLimiter to supply a
+// custom limiter, or one shared across multiple Sessions.
 func NewSession(provider auth.Provider, location *Location, debug bool) *Session {
-	return &Session{
+	return NewSessionWithLimiter(provider, location, debug, nil)
+}
+
+// NewSessionWithLimiter constructs a Pokémon Go RPC API client that
+// consults limiter before every outbound RPC. Passing the same limiter to
+// multiple Sessions shares one rate budget across all of them. A nil
+// limiter falls back to NewSession's conservative default.
+func NewSessionWithLimiter(provider auth.Provider, location *Location, debug bool, limiter RateLimiter) *Session {
+	if limiter == nil {
+		limiter = unlimited()
+	}
+	s := &Session{
 		location: location,
 		rpc:      rpc.NewClient(),
 		provider: provider,
 		debug:    debug,
+		limiter:  limiter,
 	}
+	s.init()
+	return s
 }
 
 func (s *Session) setURL(urlToken string) {
@@ -72,8 +92,31 @@ func (s *Session) getURL() string {
 	return url
 }
 
-// Call queries the Pokémon Go API through RPC protobuf
-func (s *Session) Call(requests []*protos.Request) (*protos.ResponseEnvelope, error) {
+// CallContext queries the Pokémon Go API through RPC protobuf, bounding the
+// round-trip by ctx and by any deadline set with SetReadDeadline or
+// SetWriteDeadline.
+func (s *Session) CallContext(ctx context.Context, requests []*protos.Request) (*protos.ResponseEnvelope, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.readCancel():
+			cancel()
+		case <-s.writeCancel():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	requestTypes := make([]protos.RequestType, len(requests))
+	for i, req := range requests {
+		requestTypes[i] = req.RequestType
+	}
+	if err := s.limiter.Wait(ctx, requestTypes...); err != nil {
+		return nil, err
+	}
 
 	auth := &protos.RequestEnvelope_AuthInfo{
 		Provider: s.provider.GetProviderString(),
@@ -101,7 +144,10 @@ func (s *Session) Call(requests []*protos.Request) (*protos.ResponseEnvelope, er
 		log.Println(proto.MarshalTextString(requestEnvelope))
 	}
 
-	responseEnvelope, err := s.rpc.Request(s.getURL(), requestEnvelope)
+	responseEnvelope, err := s.rpc.RequestContext(ctx, s.getURL(), requestEnvelope)
+	if err == nil {
+		s.limiter.Backoff(responseEnvelope.StatusCode)
+	}
 
 	if s.debug {
 		log.Println(proto.MarshalTextString(responseEnvelope))
@@ -110,45 +156,34 @@ func (s *Session) Call(requests []*protos.Request) (*protos.ResponseEnvelope, er
 	return responseEnvelope, err
 }
 
-// Init initializes the client by performing full authentication
-func (s *Session) Init() error {
+// Call queries the Pokémon Go API through RPC protobuf. It is equivalent to
+// CallContext(context.Background(), requests) for callers that don't need to
+// bound or cancel the round-trip.
+func (s *Session) Call(requests []*protos.Request) (*protos.ResponseEnvelope, error) {
+	return s.CallContext(context.Background(), requests)
+}
+
+// InitContext initializes the client by performing full authentication,
+// aborting early if ctx is cancelled or its deadline expires.
+func (s *Session) InitContext(ctx context.Context) error {
 	_, err := s.provider.Login()
 	if err != nil {
 		return err
 	}
 
-	requests := generateRequests()
-	requests = append(requests, &protos.Request{
-		RequestType: protos.RequestType_GET_PLAYER,
-	})
-
-	requests = append(requests, &protos.Request{
-		RequestType: protos.RequestType_GET_HATCHED_EGGS,
-	})
-
-	requests = append(requests, &protos.Request{
-		RequestType: protos.RequestType_GET_INVENTORY,
-	})
-
-	requests = append(requests, &protos.Request{
-		RequestType: protos.RequestType_CHECK_AWARDED_BADGES,
-	})
-
-	settingsMessage, _ := proto.Marshal(&protos.DownloadSettingsMessage{
-		Hash: downloadSettingsHash,
-	})
+	builder := NewRequestBuilder().
+		GetPlayer().
+		GetHatchedEggs().
+		GetInventory(0).
+		CheckAwardedBadges().
+		DownloadSettings(downloadSettingsHash)
 
-	requests = append(requests, &protos.Request{
-		RequestType:    protos.RequestType_DOWNLOAD_SETTINGS,
-		RequestMessage: settingsMessage,
-	})
-
-	response, err := s.Call(requests)
+	response, err := s.Do(ctx, builder)
 	if err != nil {
 		return err
 	}
 
-	url := response.ApiUrl
+	url := response.ApiURL()
 	if url == "" {
 		return fmt.Errorf("Could not initialize session, the service might be down")
 	}
@@ -157,77 +192,52 @@ func (s *Session) Init() error {
 	return nil
 }
 
-// Announce publishes the player's presence and returns the map environment
-func (s *Session) Announce() (mapObjects *protos.GetMapObjectsResponse, err error) {
+// Init initializes the client by performing full authentication. It is
+// equivalent to InitContext(context.Background()).
+func (s *Session) Init() error {
+	return s.InitContext(context.Background())
+}
+
+// AnnounceContext publishes the player's presence and returns the map
+// environment, aborting early if ctx is cancelled or its deadline expires.
+func (s *Session) AnnounceContext(ctx context.Context) (mapObjects *protos.GetMapObjectsResponse, err error) {
 
 	cellIDs := getCellIDs(s.location)
 	lastTimestamp := time.Now().Unix() * 1000
 
-	requests := generateRequests()
-
-	// Request the map objects based on my current location and route cell ids
-	getMapObjectsMessage, _ := proto.Marshal(&protos.GetMapObjectsMessage{
-		// Traversed route since last supposed last heartbeat
-		CellId: cellIDs,
-
-		// Timestamps in milliseconds corresponding to each route cell id
-		SinceTimestampMs: make([]int64, len(cellIDs)),
-
-		// Current longitide and latitude
-		Longitude: s.location.Lon,
-		Latitude:  s.location.Lat,
-	})
-
-	requests = append(requests, &protos.Request{
-		RequestType:    protos.RequestType_GET_MAP_OBJECTS,
-		RequestMessage: getMapObjectsMessage,
-	})
-
-	requests = append(requests, &protos.Request{
-		RequestType: protos.RequestType_GET_HATCHED_EGGS,
-	})
-
-	// Request the inventory with a message containing the current time
-	getInventoryMessage, _ := proto.Marshal(&protos.GetInventoryMessage{
-		LastTimestampMs: lastTimestamp,
-	})
-
-	requests = append(requests, &protos.Request{
-		RequestType:    protos.RequestType_GET_INVENTORY,
-		RequestMessage: getInventoryMessage,
-	})
-
-	requests = append(requests, &protos.Request{
-		RequestType: protos.RequestType_CHECK_AWARDED_BADGES,
-	})
+	builder := NewRequestBuilder().
+		// Request the map objects based on my current location and
+		// route cell ids, traversed since last supposed last heartbeat
+		GetMapObjects(cellIDs, make([]int64, len(cellIDs)), s.location.Lat, s.location.Lon).
+		GetHatchedEggs().
+		GetInventory(lastTimestamp).
+		CheckAwardedBadges().
+		DownloadSettings(downloadSettingsHash)
 
-	settingsMessage, _ := proto.Marshal(&protos.DownloadSettingsMessage{
-		Hash: downloadSettingsHash,
-	})
-
-	requests = append(requests, &protos.Request{
-		RequestType:    protos.RequestType_DOWNLOAD_SETTINGS,
-		RequestMessage: settingsMessage,
-	})
-
-	response, err := s.Call(requests)
+	response, err := s.Do(ctx, builder)
 	if err != nil {
 		return mapObjects, &RequestError{}
 	}
 
-	mapObjects = &protos.GetMapObjectsResponse{}
-	proto.Unmarshal(response.Returns[0], mapObjects)
-	return mapObjects, GetErrorFromStatus(response.StatusCode)
+	mapObjects = response.MapObjects()
+	return mapObjects, GetErrorFromStatus(response.StatusCode())
 }
 
-// GetPlayer returns the current player profile
-func (s *Session) GetPlayer() (player *protos.GetPlayerResponse, err error) {
+// Announce publishes the player's presence and returns the map environment.
+// It is equivalent to AnnounceContext(context.Background()).
+func (s *Session) Announce() (mapObjects *protos.GetMapObjectsResponse, err error) {
+	return s.AnnounceContext(context.Background())
+}
+
+// GetPlayerContext returns the current player profile, aborting early if
+// ctx is cancelled or its deadline expires.
+func (s *Session) GetPlayerContext(ctx context.Context) (player *protos.GetPlayerResponse, err error) {
 	requests := generateRequests()
 	requests = append(requests, &protos.Request{
 		RequestType: protos.RequestType_GET_PLAYER,
 	})
 
-	response, err := s.Call(requests)
+	response, err := s.CallContext(ctx, requests)
 	if err != nil {
 		return player, err
 	}
@@ -238,14 +248,21 @@ func (s *Session) GetPlayer() (player *protos.GetPlayerResponse, err error) {
 	return player, nil
 }
 
-// GetInventory returns the player items
-func (s *Session) GetInventory() (inventory *protos.GetInventoryResponse, err error) {
+// GetPlayer returns the current player profile. It is equivalent to
+// GetPlayerContext(context.Background()).
+func (s *Session) GetPlayer() (player *protos.GetPlayerResponse, err error) {
+	return s.GetPlayerContext(context.Background())
+}
+
+// GetInventoryContext returns the player items, aborting early if ctx is
+// cancelled or its deadline expires.
+func (s *Session) GetInventoryContext(ctx context.Context) (inventory *protos.GetInventoryResponse, err error) {
 	requests := generateRequests()
 	requests = append(requests, &protos.Request{
 		RequestType: protos.RequestType_GET_INVENTORY,
 	})
 
-	response, err := s.Call(requests)
+	response, err := s.CallContext(ctx, requests)
 	if err != nil {
 		return inventory, err
 	}
@@ -255,3 +272,9 @@ func (s *Session) GetInventory() (inventory *protos.GetInventoryResponse, err er
 
 	return inventory, nil
 }
+
+// GetInventory returns the player items. It is equivalent to
+// GetInventoryContext(context.Background()).
+func (s *Session) GetInventory() (inventory *protos.GetInventoryResponse, err error) {
+	return s.GetInventoryContext(context.Background())
+}
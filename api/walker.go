@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/pkmngo-odi/pogo-protos"
+)
+
+// maxWalkSpeedMetersPerSecond caps how fast a Walker may move, matching a
+// plausible human jog / slow vehicle speed so ticks don't trip Niantic's
+// speed- and trip-detection soft bans.
+const maxWalkSpeedMetersPerSecond = 25.0 // ~90 km/h
+
+// Update is emitted by Walker.Walk on every tick: the Session's new
+// location, and the GetMapObjectsResponse from the Announce made there.
+type Update struct {
+	Location   Location
+	MapObjects *protos.GetMapObjectsResponse
+}
+
+// Walker advances a Session's location along a sequence of waypoints using
+// great-circle interpolation, Announcing on every tick so the caller sees a
+// continuous stream of map updates as if a real client were walking the
+// route, rather than teleporting between Announce calls.
+type Walker struct {
+	session      *Session
+	waypoints    []Location
+	speed        float64
+	tickInterval time.Duration
+}
+
+// NewWalker returns a Walker that moves session's location along waypoints
+// at speedMetersPerSecond (capped to maxWalkSpeedMetersPerSecond),
+// Announcing every tickInterval.
+func NewWalker(session *Session, waypoints []Location, speedMetersPerSecond float64, tickInterval time.Duration) *Walker {
+	if speedMetersPerSecond > maxWalkSpeedMetersPerSecond {
+		speedMetersPerSecond = maxWalkSpeedMetersPerSecond
+	}
+	return &Walker{
+		session:      session,
+		waypoints:    waypoints,
+		speed:        speedMetersPerSecond,
+		tickInterval: tickInterval,
+	}
+}
+
+// advanceLeg consumes as many consecutive legs of the route as traveled
+// covers, starting from leg, and returns the index of the leg currently
+// being walked along with the remaining distance travelled into it. A
+// single tick can cover more than one leg when waypoints are closely
+// spaced, so this is a loop rather than a single leg++ step. A zero-length
+// leg (coincident waypoints, e.g. a paused GPS point) is always considered
+// already covered and skipped over regardless of traveled, so the walker
+// can't get stuck re-Announcing from the same spot forever. It stops at
+// the final waypoint rather than indexing past the end of waypoints.
+func advanceLeg(waypoints []Location, leg int, traveled float64) (int, float64) {
+	for leg < len(waypoints)-1 {
+		legDistance := distanceMeters(waypoints[leg], waypoints[leg+1])
+		if legDistance <= 0 {
+			leg++
+			continue
+		}
+		if traveled < legDistance {
+			break
+		}
+		traveled -= legDistance
+		leg++
+	}
+	return leg, traveled
+}
+
+// Walk advances along the route in a background goroutine until every
+// waypoint has been reached, ctx is cancelled, or an Announce fails,
+// emitting an Update on the returned channel after every tick; Walk itself
+// returns immediately, and the channel is closed once the walk ends.
+// Because each tick Announces through the Session's RateLimiter, a tick
+// that would exceed the budget simply blocks until capacity frees up rather
+// than piling up ticks.
+func (w *Walker) Walk(ctx context.Context) <-chan Update {
+	updates := make(chan Update)
+
+	go func() {
+		defer close(updates)
+
+		if len(w.waypoints) < 2 {
+			return
+		}
+
+		leg := 0
+		traveled := 0.0
+
+		for leg < len(w.waypoints)-1 {
+			if ctx.Err() != nil {
+				return
+			}
+
+			traveled += w.speed * w.tickInterval.Seconds()
+			leg, traveled = advanceLeg(w.waypoints, leg, traveled)
+
+			if leg >= len(w.waypoints)-1 {
+				last := w.waypoints[len(w.waypoints)-1]
+				w.session.location.Lat = last.Lat
+				w.session.location.Lon = last.Lon
+			} else {
+				from := w.waypoints[leg]
+				to := w.waypoints[leg+1]
+				fraction := 0.0
+				if legDistance := distanceMeters(from, to); legDistance > 0 {
+					fraction = traveled / legDistance
+				}
+				point := s2.Interpolate(fraction, pointFromLocation(from), pointFromLocation(to))
+				ll := s2.LatLngFromPoint(point)
+				w.session.location.Lat = ll.Lat.Degrees()
+				w.session.location.Lon = ll.Lon.Degrees()
+			}
+
+			mapObjects, err := w.session.AnnounceContext(ctx)
+			if err != nil {
+				return
+			}
+
+			select {
+			case updates <- Update{Location: *w.session.location, MapObjects: mapObjects}:
+			case <-ctx.Done():
+				return
+			}
+
+			timer := time.NewTimer(w.tickInterval)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+
+	return updates
+}
+
+func pointFromLocation(l Location) s2.Point {
+	return s2.PointFromLatLng(s2.LatLngFromDegrees(l.Lat, l.Lon))
+}
@@ -0,0 +1,82 @@
+package api
+
+import "testing"
+
+func TestAdvanceLegNoOvershoot(t *testing.T) {
+	waypoints := []Location{
+		{Lat: 0, Lon: 0},
+		{Lat: 0.001, Lon: 0},
+	}
+
+	leg, traveled := advanceLeg(waypoints, 0, 5)
+	if leg != 0 {
+		t.Fatalf("leg = %d, want 0", leg)
+	}
+	if traveled != 5 {
+		t.Fatalf("traveled = %v, want 5", traveled)
+	}
+}
+
+func TestAdvanceLegConsumesMultipleLegsPerTick(t *testing.T) {
+	waypoints := []Location{
+		{Lat: 37.0000, Lon: -122.0},
+		{Lat: 37.0001, Lon: -122.0},
+		{Lat: 37.0002, Lon: -122.0},
+		{Lat: 37.0010, Lon: -122.0},
+	}
+
+	legOne := distanceMeters(waypoints[0], waypoints[1])
+	legTwo := distanceMeters(waypoints[1], waypoints[2])
+	const remainder = 2.5
+
+	leg, traveled := advanceLeg(waypoints, 0, legOne+legTwo+remainder)
+
+	if leg != 2 {
+		t.Fatalf("leg = %d, want 2 (one Walk tick should cross both closely-spaced legs)", leg)
+	}
+	if diff := traveled - remainder; diff > 1e-6 || diff < -1e-6 {
+		t.Fatalf("traveled = %v, want ~%v", traveled, remainder)
+	}
+}
+
+func TestAdvanceLegStopsAtFinalWaypoint(t *testing.T) {
+	waypoints := []Location{
+		{Lat: 0, Lon: 0},
+		{Lat: 0.0001, Lon: 0},
+	}
+
+	leg, _ := advanceLeg(waypoints, 0, 1e9)
+	if want := len(waypoints) - 1; leg != want {
+		t.Fatalf("leg = %d, want %d (clamped to final waypoint)", leg, want)
+	}
+}
+
+func TestAdvanceLegSkipsCoincidentWaypoints(t *testing.T) {
+	// A paused GPS point or a deduped export can repeat a waypoint,
+	// producing a zero-length leg. advanceLeg must skip straight over it
+	// regardless of traveled, rather than getting stuck re-offering the
+	// same leg forever.
+	waypoints := []Location{
+		{Lat: 0, Lon: 0},
+		{Lat: 0, Lon: 0},
+		{Lat: 0.001, Lon: 0},
+	}
+
+	leg, traveled := advanceLeg(waypoints, 0, 3)
+	if leg != 1 {
+		t.Fatalf("leg = %d, want 1 (coincident leg skipped)", leg)
+	}
+	if traveled != 3 {
+		t.Fatalf("traveled = %v, want 3 (unchanged by the zero-length leg)", traveled)
+	}
+
+	// Repeated ticks with small traveled values must still make it past
+	// the zero-length leg instead of getting stuck at leg 0 forever.
+	leg, traveled = advanceLeg(waypoints, 0, 0)
+	if leg != 1 {
+		t.Fatalf("leg = %d, want 1 even with traveled = 0", leg)
+	}
+	if traveled != 0 {
+		t.Fatalf("traveled = %v, want 0", traveled)
+	}
+}